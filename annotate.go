@@ -0,0 +1,154 @@
+// Copyright 2024 Kyodo Tech合同会社
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchid
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Annotation is a single labeled table of analysis results attached to a
+// node, e.g. the gen/kill/on-entry/on-exit bit sets of a dataflow analysis.
+// Rows is rendered verbatim, one row per table row; callers that want a
+// header row should make it the first entry of Rows.
+type Annotation struct {
+	Label string
+	Rows  [][]string
+}
+
+// Annotator lets callers plug an arbitrary static analysis - variable
+// liveness, token flow, policy checks, cost estimates, and so on - into
+// ExportDotWithStatus/ExportMermaid. Annotate is called once per node and
+// its result is embedded into the node's rendered label.
+type Annotator interface {
+	Annotate(node *Node) []Annotation
+}
+
+// ApplyAnnotators runs each Annotator over nodes and merges the resulting
+// Annotations into base, returning a new map so callers don't have to
+// mutate their own nodeToMetadata. base may be nil.
+func ApplyAnnotators(base map[string]NodeMetadata, nodes []*Node, annotators ...Annotator) map[string]NodeMetadata {
+	merged := make(map[string]NodeMetadata, len(base))
+	for name, metadata := range base {
+		merged[name] = metadata
+	}
+
+	for _, node := range nodes {
+		metadata := merged[node.key()]
+		for _, annotator := range annotators {
+			metadata.Annotations = append(metadata.Annotations, annotator.Annotate(node)...)
+		}
+		merged[node.key()] = metadata
+	}
+
+	return merged
+}
+
+// dotAnnotatedNode renders key as a shape=plaintext HTML-like <TABLE> label,
+// one section per Annotation, in place of the usual bare DOT node
+// definition. base is the node's usual start/parallel DOT attribute string
+// (see dotNodeAttrs) - shape=plaintext makes Graphviz ignore a node's own
+// shape/color/fillcolor attributes, so base can't just be appended to
+// nodeAttrs below; dotBaseFillColor instead maps it onto the title row's
+// BGCOLOR, the same way dotPhaseFillColor does for status, so annotating a
+// node doesn't silently drop its start/parallel classification. key,
+// Annotation.Label and every row cell are HTML-escaped, since Graphviz
+// parses this label as XML and an unescaped "<", ">" or "&" in analysis
+// output would otherwise produce invalid DOT.
+//
+// When nodeStatus carries an entry for key, its phase is rendered as a
+// colored header row (mirroring dotPhaseFillColor) and its tooltip as a
+// node attribute, the same overlay dotNodeAttrs applies to plain nodes, so
+// annotated nodes don't regress to a status-less table. A retried node
+// (Attempts > 1) gets a dashed table border.
+func dotAnnotatedNode(key, base string, anns []Annotation, nodeStatus map[string]NodeStatus) string {
+	cols := 1
+	for _, ann := range anns {
+		for _, row := range ann.Rows {
+			if len(row) > cols {
+				cols = len(row)
+			}
+		}
+	}
+
+	escapedName := html.EscapeString(key)
+
+	titleBG := ""
+	if color, ok := dotBaseFillColor(base); ok {
+		titleBG = fmt.Sprintf(" BGCOLOR=%q", color)
+	}
+
+	tableAttrs := "BORDER=\"0\" CELLBORDER=\"1\" CELLSPACING=\"0\""
+	nodeAttrs := ""
+	var statusRow string
+	if status, ok := nodeStatus[key]; ok {
+		if status.Attempts > 1 {
+			tableAttrs = "BORDER=\"1\" STYLE=\"DASHED\" CELLBORDER=\"1\" CELLSPACING=\"0\""
+		}
+		nodeAttrs = fmt.Sprintf(", tooltip=%q", dotStatusTooltip(status))
+		statusRow = fmt.Sprintf("<TR><TD COLSPAN=\"%d\" BGCOLOR=%q>%s</TD></TR>\n", cols, dotPhaseFillColor(status.Phase), html.EscapeString(string(status.Phase)))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\"%s\" [shape=plaintext%s, label=<\n", key, nodeAttrs)
+	fmt.Fprintf(&b, "<TABLE %s>\n", tableAttrs)
+	fmt.Fprintf(&b, "<TR><TD COLSPAN=\"%d\"%s><B>%s</B></TD></TR>\n", cols, titleBG, escapedName)
+	b.WriteString(statusRow)
+
+	for _, ann := range anns {
+		fmt.Fprintf(&b, "<TR><TD COLSPAN=\"%d\"><I>%s</I></TD></TR>\n", cols, html.EscapeString(ann.Label))
+		for _, row := range ann.Rows {
+			b.WriteString("<TR>")
+			for _, cell := range row {
+				b.WriteString("<TD>" + html.EscapeString(cell) + "</TD>")
+			}
+			b.WriteString("</TR>\n")
+		}
+	}
+
+	b.WriteString("</TABLE>>];\n")
+	return b.String()
+}
+
+// mermaidLabelReplacer escapes characters that are significant to Mermaid's
+// flowchart label syntax, using the "#NNN;" character-code form Mermaid
+// itself documents for escaping within a label. Without it, analyzer output
+// containing "]" can close a node's "[...]" label early and inject
+// arbitrary following text - e.g. a row value of "live]-->evil" - as new
+// Mermaid syntax, the same class of bug dotAnnotatedNode was fixed for.
+var mermaidLabelReplacer = strings.NewReplacer(
+	"[", "#91;",
+	"]", "#93;",
+	"\"", "#quot;",
+)
+
+// mermaidAnnotationSuffix renders anns as "<br>"-separated lines, since
+// Mermaid node labels have no table primitive. Label and row content is
+// escaped via mermaidLabelReplacer since it isn't a trusted literal.
+func mermaidAnnotationSuffix(anns []Annotation) string {
+	var b strings.Builder
+	for _, ann := range anns {
+		b.WriteString("<br><b>" + mermaidLabelReplacer.Replace(ann.Label) + "</b>")
+		for _, row := range ann.Rows {
+			escaped := make([]string, len(row))
+			for i, cell := range row {
+				escaped[i] = mermaidLabelReplacer.Replace(cell)
+			}
+			b.WriteString("<br>" + strings.Join(escaped, ": "))
+		}
+	}
+	return b.String()
+}
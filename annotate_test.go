@@ -0,0 +1,55 @@
+// Copyright 2024 Kyodo Tech合同会社
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchid
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMermaidAnnotationSuffixEscapesBrackets(t *testing.T) {
+	anns := []Annotation{{Rows: [][]string{{"live]-->evil"}}}}
+
+	got := mermaidAnnotationSuffix(anns)
+	if strings.Contains(got, "]-->") {
+		t.Fatalf("mermaidAnnotationSuffix() = %q, want no unescaped \"]-->\"", got)
+	}
+}
+
+func TestDotAnnotatedNodeEscapesHTML(t *testing.T) {
+	anns := []Annotation{{Label: "live<&>", Rows: [][]string{{"<script>&boom"}}}}
+
+	got := dotAnnotatedNode("node<A>", "", anns, nil)
+	if strings.Contains(got, "<script>") || strings.Contains(got, "live<&>") {
+		t.Fatalf("dotAnnotatedNode() = %q, want row/label content HTML-escaped", got)
+	}
+	if !strings.Contains(got, "&lt;script&gt;&amp;boom") {
+		t.Fatalf("dotAnnotatedNode() = %q, want escaped row content", got)
+	}
+}
+
+func TestDotAnnotatedNodeKeepsBaseClassification(t *testing.T) {
+	anns := []Annotation{{Label: "liveness"}}
+
+	if got := dotAnnotatedNode("start", "shape=doublecircle, color=green", anns, nil); !strings.Contains(got, `BGCOLOR="green"`) {
+		t.Fatalf("dotAnnotatedNode(start base) = %q, want a green title BGCOLOR", got)
+	}
+	if got := dotAnnotatedNode("p1", "style=filled, fillcolor=lightblue", anns, nil); !strings.Contains(got, `BGCOLOR="lightblue"`) {
+		t.Fatalf("dotAnnotatedNode(parallel base) = %q, want a lightblue title BGCOLOR", got)
+	}
+	if got := dotAnnotatedNode("plain", "", anns, nil); strings.Contains(got, "BGCOLOR") {
+		t.Fatalf("dotAnnotatedNode(no base) = %q, want no title BGCOLOR", got)
+	}
+}
@@ -20,9 +20,95 @@ import (
 	"fmt"
 	"html/template"
 	"os"
+	"strings"
+	"time"
 )
 
+// Phase is the runtime execution phase of a workflow node, mirrored onto
+// ExportDotWithStatus / ExportMermaidWithStatus via NodeStatus.
+type Phase string
+
+const (
+	PhasePending   Phase = "Pending"
+	PhaseRunning   Phase = "Running"
+	PhaseSucceeded Phase = "Succeeded"
+	PhaseFailed    Phase = "Failed"
+	PhaseSkipped   Phase = "Skipped"
+	PhaseError     Phase = "Error"
+)
+
+// NodeStatus carries the live execution state of a single node, as recorded
+// by the runtime engine, so it can be overlaid on the exported graph.
+type NodeStatus struct {
+	Phase      Phase
+	Message    string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Duration   time.Duration
+	Attempts   int // number of execution attempts so far, >1 indicates a retry
+}
+
+// EdgeStatus records whether an edge was actually traversed during
+// execution, letting renderers distinguish the execution path taken from
+// the full topology.
+type EdgeStatus struct {
+	Traversed bool
+}
+
+// edgeStatusKey builds the map key used to look up an edge's EdgeStatus.
+func edgeStatusKey(from, to string) string {
+	return from + "->" + to
+}
+
+// dotPhaseFillColor maps a runtime phase to a DOT fillcolor.
+func dotPhaseFillColor(phase Phase) string {
+	switch phase {
+	case PhaseSucceeded:
+		return "green"
+	case PhaseRunning:
+		return "blue"
+	case PhaseFailed, PhaseError:
+		return "red"
+	default: // PhasePending, PhaseSkipped
+		return "grey"
+	}
+}
+
+// dotBaseFillColor extracts the fillcolor/color a start or parallel node's
+// base DOT attribute string (see exportDotRecursive) would normally render
+// with, for dotAnnotatedNode to apply to its title row instead, since
+// shape=plaintext ignores those attributes on the node itself. ok is false
+// for an unstyled (exit or plain) node.
+func dotBaseFillColor(base string) (color string, ok bool) {
+	switch {
+	case strings.Contains(base, "fillcolor=lightblue"):
+		return "lightblue", true
+	case strings.Contains(base, "color=green"):
+		return "green", true
+	default:
+		return "", false
+	}
+}
+
+// mermaidPhaseClass maps a runtime phase to the Mermaid classDef name
+// emitted by ExportMermaidWithStatus.
+func mermaidPhaseClass(phase Phase) string {
+	switch phase {
+	case PhaseSucceeded:
+		return "phaseSucceeded"
+	case PhaseRunning:
+		return "phaseRunning"
+	case PhaseFailed, PhaseError:
+		return "phaseFailed"
+	default: // PhasePending, PhaseSkipped
+		return "phasePending"
+	}
+}
+
 func (wf *Workflow) isStartNode(node *Node) bool {
+	if wf.isExitNode(node) {
+		return false
+	}
 	for _, n := range wf.startingGraphNodes() {
 		if n.ID() == node.ID {
 			return true
@@ -32,12 +118,29 @@ func (wf *Workflow) isStartNode(node *Node) bool {
 }
 
 func (wf *Workflow) isParallelNode(node *Node) bool {
+	if wf.isExitNode(node) {
+		return false
+	}
 	parallelNodes := markParallelNodes(wf.directedGraph, wf.spawningParallelNodes())
 	_, ok := parallelNodes[node.ID]
 	return ok
 }
 
 func (wf *Workflow) ExportDot(indent string, optionalChildWorkflows map[string]*Workflow) []byte {
+	return wf.ExportDotWithStatus(indent, optionalChildWorkflows, nil, nil, nil)
+}
+
+// ExportDotWithStatus is ExportDot with a runtime status overlay and
+// NodeMetadata.Annotations support. nodeStatus and edgeStatus are keyed by
+// Node identity (see Node.key) and by edgeStatusKey(From, To) respectively;
+// nodeToMetadata is keyed by Node identity. All three may be nil to render
+// the plain topology. Nodes carrying a NodeStatus are filled per dotPhaseFillColor,
+// annotated with a tooltip combining Message and Duration, and given a
+// dashed border when Attempts indicates a retry. Edges marked Traversed are
+// rendered bold; the rest dotted. Nodes whose NodeMetadata carries
+// Annotations are rendered as a shape=plaintext HTML <TABLE> label instead
+// of the usual bare node.
+func (wf *Workflow) ExportDotWithStatus(indent string, optionalChildWorkflows map[string]*Workflow, nodeToMetadata map[string]NodeMetadata, nodeStatus map[string]NodeStatus, edgeStatus map[string]EdgeStatus) []byte {
 	var dotData []byte
 	dotData = append(dotData, []byte("digraph \"")...)
 	dotData = append(dotData, []byte(wf.Name)...)
@@ -45,13 +148,13 @@ func (wf *Workflow) ExportDot(indent string, optionalChildWorkflows map[string]*
 
 	// Keep track of visited workflows to prevent infinite recursion
 	visited := make(map[string]bool)
-	dotData = append(dotData, wf.exportDotRecursive(indent+"    ", visited, optionalChildWorkflows)...)
+	dotData = append(dotData, wf.exportDotRecursive(indent+"    ", visited, optionalChildWorkflows, nodeToMetadata, nodeStatus, edgeStatus)...)
 
 	dotData = append(dotData, []byte("}\n")...)
 	return dotData
 }
 
-func (wf *Workflow) exportDotRecursive(indent string, visited map[string]bool, optionalChildWorkflows map[string]*Workflow) []byte {
+func (wf *Workflow) exportDotRecursive(indent string, visited map[string]bool, optionalChildWorkflows map[string]*Workflow, nodeToMetadata map[string]NodeMetadata, nodeStatus map[string]NodeStatus, edgeStatus map[string]EdgeStatus) []byte {
 	var dotData []byte
 
 	if visited[wf.Name] {
@@ -64,24 +167,41 @@ func (wf *Workflow) exportDotRecursive(indent string, visited map[string]bool, o
 			continue
 		}
 
+		if anns := nodeToMetadata[node.key()].Annotations; len(anns) > 0 {
+			dotData = append(dotData, []byte(indent)...)
+			dotData = append(dotData, []byte(dotAnnotatedNode(node.key(), "shape=doublecircle, color=green", anns, nodeStatus))...)
+			continue
+		}
+
 		dotData = append(dotData, []byte(indent)...)
-		dotData = append(dotData, []byte("\""+node.ActivityName+"\"")...)
-		dotData = append(dotData, []byte(" [shape=doublecircle, color=green]")...)
+		dotData = append(dotData, []byte("\""+node.key()+"\"")...)
+		dotData = append(dotData, []byte(" ["+dotNodeAttrs("shape=doublecircle, color=green", nodeStatus, node.key())+"]")...)
 		dotData = append(dotData, []byte(";\n")...)
 	}
 
 	// Node definitions with styling
 	for _, node := range wf.Nodes {
-		if wf.isStartNode(node) {
+		if wf.isStartNode(node) || wf.isExitNode(node) {
 			continue
 		}
 
-		dotData = append(dotData, []byte(indent)...)
-		dotData = append(dotData, []byte("\""+node.ActivityName+"\"")...)
-
 		// Add styling for parallel nodes
+		base := ""
 		if wf.isParallelNode(node) {
-			dotData = append(dotData, []byte(" [style=filled, fillcolor=lightblue]")...)
+			base = "style=filled, fillcolor=lightblue"
+		}
+
+		if anns := nodeToMetadata[node.key()].Annotations; len(anns) > 0 {
+			dotData = append(dotData, []byte(indent)...)
+			dotData = append(dotData, []byte(dotAnnotatedNode(node.key(), base, anns, nodeStatus))...)
+			continue
+		}
+
+		dotData = append(dotData, []byte(indent)...)
+		dotData = append(dotData, []byte("\""+node.key()+"\"")...)
+
+		if attrs := dotNodeAttrs(base, nodeStatus, node.key()); attrs != "" {
+			dotData = append(dotData, []byte(" ["+attrs+"]")...)
 		}
 
 		dotData = append(dotData, []byte(";\n")...)
@@ -90,18 +210,37 @@ func (wf *Workflow) exportDotRecursive(indent string, visited map[string]bool, o
 	// Edge definitions
 	for _, edge := range wf.Edges {
 		dotData = append(dotData, []byte(indent)...)
-		dotData = append(dotData, []byte("\""+edge.From+"\" -> \""+edge.To+"\"")...)
+		dotData = append(dotData, []byte("\""+edge.From+"\" -> \""+edge.To+"\""+dotEdgeStatusAttrs(edgeStatus, edge.From, edge.To))...)
 		dotData = append(dotData, []byte(";\n")...)
 	}
 
+	// Exit handler: rendered in its own cluster, connected from every
+	// terminal node of the main graph with a dashed edge so readers can see
+	// its trigger set at a glance.
+	if wf.onExit != nil {
+		dotData = append(dotData, []byte(indent+"subgraph \"cluster_onExit\" {\n")...)
+		dotData = append(dotData, []byte(indent+"    label = \"onExit\";\n")...)
+		dotData = append(dotData, []byte(indent+"    \""+wf.onExit.key()+"\" ["+dotNodeAttrs("shape=doubleoctagon, style=filled, fillcolor=yellow", nodeStatus, wf.onExit.key())+"];\n")...)
+		dotData = append(dotData, []byte(indent+"}\n")...)
+
+		for _, terminal := range wf.exitNodes() {
+			if wf.isExitNode(terminal) {
+				continue
+			}
+			dotData = append(dotData, []byte(indent)...)
+			dotData = append(dotData, []byte("\""+terminal.key()+"\" -> \""+wf.onExit.key()+"\" [style=dashed]")...)
+			dotData = append(dotData, []byte(";\n")...)
+		}
+	}
+
 	// Recursively include child workflows
 	for _, node := range wf.Nodes {
-		if childWf, exists := optionalChildWorkflows[node.ActivityName]; exists {
+		if childWf, exists := optionalChildWorkflows[node.key()]; exists {
 			// Subgraph for child workflow
 			dotData = append(dotData, []byte(indent)...)
 			dotData = append(dotData, []byte("subgraph \"cluster_"+childWf.Name+"\" {\n")...)
 			dotData = append(dotData, []byte(indent+"    label = \""+childWf.Name+"\";\n")...)
-			dotData = append(dotData, childWf.exportDotRecursive(indent+"    ", visited, optionalChildWorkflows)...)
+			dotData = append(dotData, childWf.exportDotRecursive(indent+"    ", visited, optionalChildWorkflows, nodeToMetadata, nodeStatus, edgeStatus)...)
 			dotData = append(dotData, []byte(indent+"}\n")...)
 		}
 	}
@@ -109,6 +248,55 @@ func (wf *Workflow) exportDotRecursive(indent string, visited map[string]bool, o
 	return dotData
 }
 
+// dotNodeAttrs combines a node's base DOT attributes (e.g. shape/color for
+// start nodes, fill for parallel nodes) with its NodeStatus overlay, if any,
+// returning the full attribute list body (no surrounding brackets). base may
+// be "". key is the Node identity (see Node.key) nodeStatus is keyed by.
+func dotNodeAttrs(base string, nodeStatus map[string]NodeStatus, key string) string {
+	status, ok := nodeStatus[key]
+	if !ok {
+		return base
+	}
+
+	style := "filled"
+	if status.Attempts > 1 {
+		style = "filled,dashed"
+	}
+
+	attrs := fmt.Sprintf("style=%q, fillcolor=%s, tooltip=%q", style, dotPhaseFillColor(status.Phase), dotStatusTooltip(status))
+	if base == "" {
+		return attrs
+	}
+	return base + ", " + attrs
+}
+
+// dotStatusTooltip combines a NodeStatus's Message and Duration into the
+// text shown on hover.
+func dotStatusTooltip(status NodeStatus) string {
+	tooltip := string(status.Phase)
+	if status.Message != "" {
+		tooltip += ": " + status.Message
+	}
+	if status.Duration > 0 {
+		tooltip += fmt.Sprintf(" (%s)", status.Duration)
+	}
+	return tooltip
+}
+
+// dotEdgeStatusAttrs renders the DOT attribute fragment for an edge's
+// EdgeStatus: bold when traversed, dotted otherwise. Returns "" when no
+// edgeStatus map was supplied.
+func dotEdgeStatusAttrs(edgeStatus map[string]EdgeStatus, from, to string) string {
+	if edgeStatus == nil {
+		return ""
+	}
+
+	if status, ok := edgeStatus[edgeStatusKey(from, to)]; ok && status.Traversed {
+		return " [style=bold]"
+	}
+	return " [style=dotted]"
+}
+
 func (wf *Workflow) ExportDotToFile(filename string, optionalChildWorkflows map[string]*Workflow) error {
 	dotData := wf.ExportDot("    ", optionalChildWorkflows)
 
@@ -122,9 +310,10 @@ func (wf *Workflow) ExportDotToFile(filename string, optionalChildWorkflows map[
 
 // Define NodeMetadata struct with optional description and links
 type NodeMetadata struct {
-	Description string     // Node description, which may include line breaks
-	Links       []NodeLink // List of links to display within the node
-	Standalone  bool       // Flag indicating if the node should be rendered as standalone
+	Description string       // Node description, which may include line breaks
+	Links       []NodeLink   // List of links to display within the node
+	Standalone  bool         // Flag indicating if the node should be rendered as standalone
+	Annotations []Annotation // Structured analysis results (see Annotator) to embed in the rendered node
 
 }
 
@@ -136,15 +325,26 @@ type NodeLink struct {
 
 // ExportMermaid generates the Mermaid representation of the workflow.
 // Optionally, it can include child workflows as subgraphs and requires a map of
-// node names to corresponding child workflows they spawn. It also accepts an optional
-// nodeToMetadata parameter to add descriptions and links to nodes.
+// Node identities (see Node.key) to the child workflows they spawn. It also
+// accepts an optional nodeToMetadata parameter to add descriptions and links
+// to nodes.
 func (wf *Workflow) ExportMermaid(indent string, nodeToChildWorkflows map[string]*Workflow, nodeToMetadata map[string]NodeMetadata) []byte {
+	return wf.ExportMermaidWithStatus(indent, nodeToChildWorkflows, nodeToMetadata, nil, nil)
+}
+
+// ExportMermaidWithStatus is ExportMermaid with a runtime status overlay.
+// nodeStatus and edgeStatus are keyed by Node identity (see Node.key) and by
+// edgeStatusKey(From, To) respectively, and may be nil to render the plain
+// topology. Nodes carrying a NodeStatus get their Duration appended to the
+// label and are assigned one of the phaseXxx classDefs below; edges marked
+// Traversed are rendered with a solid arrow, the rest with a dotted one.
+func (wf *Workflow) ExportMermaidWithStatus(indent string, nodeToChildWorkflows map[string]*Workflow, nodeToMetadata map[string]NodeMetadata, nodeStatus map[string]NodeStatus, edgeStatus map[string]EdgeStatus) []byte {
 	var mermaidData []byte
 	mermaidData = append(mermaidData, []byte("flowchart TD\n")...)
 
 	visited := make(map[string]bool)
 	classAssignments := make([]string, 0)
-	mermaidData = append(mermaidData, wf.exportMermaidRecursive(indent+"    ", visited, nodeToChildWorkflows, nodeToMetadata, "", &classAssignments)...)
+	mermaidData = append(mermaidData, wf.exportMermaidRecursive(indent+"    ", visited, nodeToChildWorkflows, nodeToMetadata, "", &classAssignments, nodeStatus, edgeStatus)...)
 
 	mermaidData = append(mermaidData, []byte("\n")...)
 
@@ -165,10 +365,43 @@ func (wf *Workflow) ExportMermaid(indent string, nodeToChildWorkflows map[string
 	// Add class definitions at the end
 	mermaidData = append(mermaidData, []byte("classDef startNode fill:#9f6,stroke:#333,stroke-width:4px;\n")...)
 	mermaidData = append(mermaidData, []byte("classDef parallelNode fill:#6cf,stroke:#333,stroke-width:2px;\n")...)
+	if wf.onExit != nil {
+		mermaidData = append(mermaidData, []byte("classDef exitNode fill:#fe6,stroke:#333,stroke-width:2px;\n")...)
+	}
+	if nodeStatus != nil {
+		mermaidData = append(mermaidData, []byte("classDef phaseSucceeded fill:#6c6,stroke:#333,stroke-width:2px;\n")...)
+		mermaidData = append(mermaidData, []byte("classDef phaseRunning fill:#69f,stroke:#333,stroke-width:2px;\n")...)
+		mermaidData = append(mermaidData, []byte("classDef phaseFailed fill:#e66,stroke:#333,stroke-width:2px;\n")...)
+		mermaidData = append(mermaidData, []byte("classDef phasePending fill:#ccc,stroke:#333,stroke-width:2px;\n")...)
+	}
 
 	return mermaidData
 }
 
+// mermaidStatusSuffix renders the "<br>(duration)" fragment appended to a
+// node's label when a NodeStatus is recorded for it. key is the Node
+// identity (see Node.key) nodeStatus is keyed by.
+func mermaidStatusSuffix(nodeStatus map[string]NodeStatus, key string) string {
+	status, ok := nodeStatus[key]
+	if !ok || status.Duration == 0 {
+		return ""
+	}
+	return fmt.Sprintf("<br>(%s)", status.Duration)
+}
+
+// mermaidEdgeArrow returns the Mermaid arrow fragment for an edge: solid
+// when traversed, dotted when known untraversed, and the default solid
+// arrow when no edgeStatus map was supplied.
+func mermaidEdgeArrow(edgeStatus map[string]EdgeStatus, from, to string) string {
+	if edgeStatus == nil {
+		return "-->"
+	}
+	if status, ok := edgeStatus[edgeStatusKey(from, to)]; ok && status.Traversed {
+		return "-->"
+	}
+	return "-.->"
+}
+
 // Function to render standalone nodes
 func (wf *Workflow) renderStandaloneNode(indent, nodeName string, metadata NodeMetadata) string {
 	var nodeLabel = nodeName
@@ -186,7 +419,7 @@ func (wf *Workflow) renderStandaloneNode(indent, nodeName string, metadata NodeM
 }
 
 // Recursive function to render nodes, edges, and metadata as Mermaid syntax
-func (wf *Workflow) exportMermaidRecursive(indent string, visited map[string]bool, nodeToChildWorkflows map[string]*Workflow, nodeToMetadata map[string]NodeMetadata, prefix string, classAssignments *[]string) []byte {
+func (wf *Workflow) exportMermaidRecursive(indent string, visited map[string]bool, nodeToChildWorkflows map[string]*Workflow, nodeToMetadata map[string]NodeMetadata, prefix string, classAssignments *[]string, nodeStatus map[string]NodeStatus, edgeStatus map[string]EdgeStatus) []byte {
 	var mermaidData []byte
 
 	if visited[wf.Name] {
@@ -198,20 +431,22 @@ func (wf *Workflow) exportMermaidRecursive(indent string, visited map[string]boo
 	startNodes := wf.startingNodes()
 	for _, node := range startNodes {
 		// Check if the node is standalone; if so, skip in this section
-		if metadata, ok := nodeToMetadata[node.ActivityName]; ok && metadata.Standalone {
+		if metadata, ok := nodeToMetadata[node.key()]; ok && metadata.Standalone {
 			continue
 		}
 
-		nodeName := prefix + node.ActivityName
-		nodeLabel := node.ActivityName
+		nodeName := prefix + node.key()
+		nodeLabel := node.key()
 
 		// Check for metadata and add description and links if present
-		if metadata, ok := nodeToMetadata[node.ActivityName]; ok {
+		if metadata, ok := nodeToMetadata[node.key()]; ok {
 			nodeLabel += "<br>" + metadata.Description
 			for _, link := range metadata.Links {
 				nodeLabel += fmt.Sprintf(" <b><a href='%s' target='_blank'>%s</a></b>", link.URI, link.Name)
 			}
 		}
+		nodeLabel += mermaidStatusSuffix(nodeStatus, node.key())
+		nodeLabel += mermaidAnnotationSuffix(nodeToMetadata[node.key()].Annotations)
 
 		nodeLine := indent + nodeName + "[" + nodeLabel + "]\n"
 		mermaidData = append(mermaidData, []byte(nodeLine)...)
@@ -220,31 +455,39 @@ func (wf *Workflow) exportMermaidRecursive(indent string, visited map[string]boo
 			mermaidData = append(mermaidData, []byte(fmt.Sprintf("click %s \"%s\" _blank\n", nodeName, *node.EditLink))...)
 		}
 
-		// Collect class assignment
-		*classAssignments = append(*classAssignments, fmt.Sprintf("class %s startNode\n", nodeName))
+		// Collect class assignment: a recorded NodeStatus takes priority over
+		// the plain startNode styling so the phase color is visible.
+		if status, ok := nodeStatus[node.key()]; ok {
+			*classAssignments = append(*classAssignments, fmt.Sprintf("class %s %s\n", nodeName, mermaidPhaseClass(status.Phase)))
+		} else {
+			*classAssignments = append(*classAssignments, fmt.Sprintf("class %s startNode\n", nodeName))
+		}
 	}
 
-	// Render remaining nodes (excluding starting nodes)
+	// Render remaining nodes (excluding starting nodes and the exit handler,
+	// which is rendered in its own subgraph below)
 	for _, node := range wf.Nodes {
-		if wf.isStartNode(node) {
-			continue // Skip already rendered starting nodes
+		if wf.isStartNode(node) || wf.isExitNode(node) {
+			continue
 		}
 
 		// Check if the node is standalone; if so, skip in this section
-		if metadata, ok := nodeToMetadata[node.ActivityName]; ok && metadata.Standalone {
+		if metadata, ok := nodeToMetadata[node.key()]; ok && metadata.Standalone {
 			continue
 		}
 
-		nodeName := prefix + node.ActivityName
-		nodeLabel := node.ActivityName
+		nodeName := prefix + node.key()
+		nodeLabel := node.key()
 
 		// Check for metadata and add description and links if present
-		if metadata, ok := nodeToMetadata[node.ActivityName]; ok {
+		if metadata, ok := nodeToMetadata[node.key()]; ok {
 			nodeLabel += "<br>" + metadata.Description
 			for _, link := range metadata.Links {
 				nodeLabel += fmt.Sprintf(" <b><a href='%s' target='_blank'>%s</a></b>", link.URI, link.Name)
 			}
 		}
+		nodeLabel += mermaidStatusSuffix(nodeStatus, node.key())
+		nodeLabel += mermaidAnnotationSuffix(nodeToMetadata[node.key()].Annotations)
 
 		nodeLine := indent + nodeName + "[" + nodeLabel + "]\n"
 		mermaidData = append(mermaidData, []byte(nodeLine)...)
@@ -253,8 +496,11 @@ func (wf *Workflow) exportMermaidRecursive(indent string, visited map[string]boo
 			mermaidData = append(mermaidData, []byte(fmt.Sprintf("click %s \"%s\" _blank\n", nodeName, *node.EditLink))...)
 		}
 
-		// Collect class assignment if node is a parallel node
-		if wf.isParallelNode(node) {
+		// Collect class assignment: a recorded NodeStatus takes priority over
+		// the plain parallelNode styling so the phase color is visible.
+		if status, ok := nodeStatus[node.key()]; ok {
+			*classAssignments = append(*classAssignments, fmt.Sprintf("class %s %s\n", nodeName, mermaidPhaseClass(status.Phase)))
+		} else if wf.isParallelNode(node) {
 			*classAssignments = append(*classAssignments, fmt.Sprintf("class %s parallelNode\n", nodeName))
 		}
 	}
@@ -275,14 +521,14 @@ func (wf *Workflow) exportMermaidRecursive(indent string, visited map[string]boo
 			for _, entryNode := range entryNodes {
 				mermaidData = append(mermaidData, []byte(indent)...)
 				mermaidData = append(mermaidData, []byte(fromNode)...)
-				mermaidData = append(mermaidData, []byte(" --> ")...)
-				mermaidData = append(mermaidData, []byte(childPrefix+entryNode.ActivityName)...)
+				mermaidData = append(mermaidData, []byte(" "+mermaidEdgeArrow(edgeStatus, edge.From, entryNode.key())+" ")...)
+				mermaidData = append(mermaidData, []byte(childPrefix+entryNode.key())...)
 				mermaidData = append(mermaidData, []byte("\n")...)
 			}
 
 			// Render the child workflow subgraph recursively
 			mermaidData = append(mermaidData, []byte(indent+"subgraph "+edge.To+"\n")...)
-			mermaidData = append(mermaidData, childWf.exportMermaidRecursive(indent+"    ", visited, nodeToChildWorkflows, nodeToMetadata, childPrefix, classAssignments)...)
+			mermaidData = append(mermaidData, childWf.exportMermaidRecursive(indent+"    ", visited, nodeToChildWorkflows, nodeToMetadata, childPrefix, classAssignments, nodeStatus, edgeStatus)...)
 			mermaidData = append(mermaidData, []byte(indent+"end\n")...)
 		} else if childWf, exists := nodeToChildWorkflows[edge.From]; exists {
 			// 'From' node is a child workflow
@@ -292,8 +538,8 @@ func (wf *Workflow) exportMermaidRecursive(indent string, visited map[string]boo
 			// Connect child workflow's exit nodes to parent node
 			for _, exitNode := range exitNodes {
 				mermaidData = append(mermaidData, []byte(indent)...)
-				mermaidData = append(mermaidData, []byte(childPrefix+exitNode.ActivityName)...)
-				mermaidData = append(mermaidData, []byte(" --> ")...)
+				mermaidData = append(mermaidData, []byte(childPrefix+exitNode.key())...)
+				mermaidData = append(mermaidData, []byte(" "+mermaidEdgeArrow(edgeStatus, exitNode.key(), edge.To)+" ")...)
 				mermaidData = append(mermaidData, []byte(toNode)...)
 				mermaidData = append(mermaidData, []byte("\n")...)
 			}
@@ -301,12 +547,31 @@ func (wf *Workflow) exportMermaidRecursive(indent string, visited map[string]boo
 			// Regular edge
 			mermaidData = append(mermaidData, []byte(indent)...)
 			mermaidData = append(mermaidData, []byte(fromNode)...)
-			mermaidData = append(mermaidData, []byte(" --> ")...)
+			mermaidData = append(mermaidData, []byte(" "+mermaidEdgeArrow(edgeStatus, edge.From, edge.To)+" ")...)
 			mermaidData = append(mermaidData, []byte(toNode)...)
 			mermaidData = append(mermaidData, []byte("\n")...)
 		}
 	}
 
+	// Exit handler: rendered in its own subgraph, connected from every
+	// terminal node of the main graph with a dotted edge.
+	if wf.onExit != nil {
+		exitNodeName := prefix + wf.onExit.key()
+
+		mermaidData = append(mermaidData, []byte(indent+"subgraph onExit\n")...)
+		mermaidData = append(mermaidData, []byte(indent+"    "+exitNodeName+"["+wf.onExit.key()+mermaidStatusSuffix(nodeStatus, wf.onExit.key())+"]\n")...)
+		mermaidData = append(mermaidData, []byte(indent+"end\n")...)
+		*classAssignments = append(*classAssignments, fmt.Sprintf("class %s exitNode\n", exitNodeName))
+
+		for _, terminal := range wf.exitNodes() {
+			if wf.isExitNode(terminal) {
+				continue
+			}
+			mermaidData = append(mermaidData, []byte(indent)...)
+			mermaidData = append(mermaidData, []byte(prefix+terminal.key()+" -.-> "+exitNodeName+"\n")...)
+		}
+	}
+
 	return mermaidData
 }
 
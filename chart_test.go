@@ -0,0 +1,102 @@
+// Copyright 2024 Kyodo Tech合同会社
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchid
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDotPhaseFillColor(t *testing.T) {
+	tests := []struct {
+		phase Phase
+		want  string
+	}{
+		{PhaseSucceeded, "green"},
+		{PhaseRunning, "blue"},
+		{PhaseFailed, "red"},
+		{PhaseError, "red"},
+		{PhasePending, "grey"},
+		{PhaseSkipped, "grey"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.phase), func(t *testing.T) {
+			if got := dotPhaseFillColor(tt.phase); got != tt.want {
+				t.Fatalf("dotPhaseFillColor(%q) = %q, want %q", tt.phase, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDotNodeAttrsDashedOnRetry(t *testing.T) {
+	nodeStatus := map[string]NodeStatus{
+		"first-try":  {Phase: PhaseFailed, Attempts: 1},
+		"retried":    {Phase: PhaseFailed, Attempts: 2},
+		"no-attempt": {Phase: PhaseRunning},
+	}
+
+	if got := dotNodeAttrs("", nodeStatus, "first-try"); strings.Contains(got, "dashed") {
+		t.Fatalf("dotNodeAttrs(first attempt) = %q, want no dashed style", got)
+	}
+	if got := dotNodeAttrs("", nodeStatus, "retried"); !strings.Contains(got, "dashed") {
+		t.Fatalf("dotNodeAttrs(retried) = %q, want dashed style", got)
+	}
+	if got := dotNodeAttrs("", nodeStatus, "no-attempt"); strings.Contains(got, "dashed") {
+		t.Fatalf("dotNodeAttrs(no attempts recorded) = %q, want no dashed style", got)
+	}
+}
+
+func TestDotEdgeStatusAttrsBoldVsDotted(t *testing.T) {
+	edgeStatus := map[string]EdgeStatus{
+		edgeStatusKey("a", "b"): {Traversed: true},
+		edgeStatusKey("b", "c"): {Traversed: false},
+	}
+
+	if got := dotEdgeStatusAttrs(edgeStatus, "a", "b"); !strings.Contains(got, "bold") {
+		t.Fatalf("dotEdgeStatusAttrs(traversed) = %q, want bold style", got)
+	}
+	if got := dotEdgeStatusAttrs(edgeStatus, "b", "c"); !strings.Contains(got, "dotted") {
+		t.Fatalf("dotEdgeStatusAttrs(not traversed) = %q, want dotted style", got)
+	}
+	if got := dotEdgeStatusAttrs(edgeStatus, "x", "y"); !strings.Contains(got, "dotted") {
+		t.Fatalf("dotEdgeStatusAttrs(unknown edge) = %q, want dotted style", got)
+	}
+	if got := dotEdgeStatusAttrs(nil, "a", "b"); got != "" {
+		t.Fatalf("dotEdgeStatusAttrs(nil map) = %q, want empty", got)
+	}
+}
+
+func TestMermaidPhaseClass(t *testing.T) {
+	tests := []struct {
+		phase Phase
+		want  string
+	}{
+		{PhaseSucceeded, "phaseSucceeded"},
+		{PhaseRunning, "phaseRunning"},
+		{PhaseFailed, "phaseFailed"},
+		{PhaseError, "phaseFailed"},
+		{PhasePending, "phasePending"},
+		{PhaseSkipped, "phasePending"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.phase), func(t *testing.T) {
+			if got := mermaidPhaseClass(tt.phase); got != tt.want {
+				t.Fatalf("mermaidPhaseClass(%q) = %q, want %q", tt.phase, got, tt.want)
+			}
+		})
+	}
+}
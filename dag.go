@@ -0,0 +1,301 @@
+// Copyright 2024 Kyodo Tech合同会社
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchid
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DAGTask is a single unit of work inside a WorkflowDAG. Instead of wiring
+// Nodes and Edges by hand, callers declare a task, the Activity it runs, and
+// the names of the tasks it depends on; Compile resolves the dependencies
+// into the equivalent Nodes/Edges form.
+type DAGTask struct {
+	Name         string
+	Activity     string
+	Dependencies []string
+
+	// Arguments are passed to Activity. A value of the form
+	// "{{tasks.<name>.outputs.<key>}}" is resolved at compile time into a
+	// reference to the named dependency's output, letting downstream tasks
+	// consume an upstream task's result.
+	Arguments map[string]string
+}
+
+// WorkflowDAG is a declarative, dependency-based alternative to specifying a
+// Workflow's Nodes and Edges directly, modeled after Argo's DAG templates.
+// Compile converts it into a Workflow.
+type WorkflowDAG struct {
+	Name  string
+	Tasks []DAGTask
+
+	// Targets names the terminal tasks of the DAG. If empty, Compile defaults
+	// it to the set of leaf tasks (tasks no other task depends on).
+	Targets []string
+}
+
+// DAGValidationError reports why a WorkflowDAG failed to compile. More than
+// one field may be populated at once.
+type DAGValidationError struct {
+	UnknownDependencies       []string // "task->dependency" pairs naming a task that doesn't exist
+	CycleMembers              []string // task names participating in a dependency cycle
+	AmbiguousTargets          []string // Targets entries that don't name a known task
+	InvalidArgumentReferences []string // "task.arg: <reason>" entries for bad "{{tasks...}}" references
+}
+
+func (e *DAGValidationError) Error() string {
+	var parts []string
+	if len(e.UnknownDependencies) > 0 {
+		parts = append(parts, fmt.Sprintf("unknown dependencies: %s", strings.Join(e.UnknownDependencies, ", ")))
+	}
+	if len(e.CycleMembers) > 0 {
+		parts = append(parts, fmt.Sprintf("dependency cycle: %s", strings.Join(e.CycleMembers, " -> ")))
+	}
+	if len(e.AmbiguousTargets) > 0 {
+		parts = append(parts, fmt.Sprintf("ambiguous targets: %s", strings.Join(e.AmbiguousTargets, ", ")))
+	}
+	if len(e.InvalidArgumentReferences) > 0 {
+		parts = append(parts, fmt.Sprintf("invalid argument references: %s", strings.Join(e.InvalidArgumentReferences, ", ")))
+	}
+	return "orchid: invalid DAG: " + strings.Join(parts, "; ")
+}
+
+func (e *DAGValidationError) empty() bool {
+	return len(e.UnknownDependencies) == 0 && len(e.CycleMembers) == 0 && len(e.AmbiguousTargets) == 0 &&
+		len(e.InvalidArgumentReferences) == 0
+}
+
+// Compile validates the DAG and converts it into a Workflow with the
+// equivalent Nodes and Edges. Tasks with no Dependencies become start nodes
+// once compiled, since startingGraphNodes already classifies any node
+// without an incoming edge as a start node. Tasks that share a single
+// dependency receive parallel edges from that dependency, which is enough
+// for markParallelNodes/spawningParallelNodes to report them as parallel via
+// isParallelNode - no separate fan-out step is needed. Each compiled Node's
+// Key is set to its DAGTask.Name (see Node.key), so sibling tasks that fan
+// out to the same Activity with different Arguments still get distinct
+// graph identities.
+func (d *WorkflowDAG) Compile() (*Workflow, error) {
+	byName := make(map[string]*DAGTask, len(d.Tasks))
+	for i := range d.Tasks {
+		t := &d.Tasks[i]
+		if _, dup := byName[t.Name]; dup {
+			return nil, fmt.Errorf("orchid: duplicate DAG task name %q", t.Name)
+		}
+		byName[t.Name] = t
+	}
+
+	verr := &DAGValidationError{}
+	for _, t := range d.Tasks {
+		for _, dep := range t.Dependencies {
+			if _, ok := byName[dep]; !ok {
+				verr.UnknownDependencies = append(verr.UnknownDependencies, t.Name+"->"+dep)
+			}
+		}
+	}
+
+	verr.CycleMembers = detectDAGCycle(d.Tasks, byName)
+
+	targets := d.Targets
+	if len(targets) == 0 {
+		targets = leafDAGTasks(d.Tasks)
+	}
+	for _, target := range targets {
+		if _, ok := byName[target]; !ok {
+			verr.AmbiguousTargets = append(verr.AmbiguousTargets, target)
+		}
+	}
+
+	if !verr.empty() {
+		return nil, verr
+	}
+
+	// Nodes are built before arguments are resolved so resolveDAGArguments
+	// can translate a "{{tasks.<name>...}}" reference into the dependency's
+	// Node key regardless of task declaration order. Key is set to the
+	// task name, not left to default to ActivityName, since sibling tasks
+	// that invoke the same Activity - the parallel fan-out case - would
+	// otherwise collide in NewWorkflow's byKey uniqueness check.
+	nodes := make([]*Node, 0, len(d.Tasks))
+	nodeByTask := make(map[string]*Node, len(d.Tasks))
+	for _, t := range d.Tasks {
+		node := &Node{ActivityName: t.Activity, Key: t.Name}
+		nodes = append(nodes, node)
+		nodeByTask[t.Name] = node
+	}
+
+	for _, t := range d.Tasks {
+		resolved, invalid := resolveDAGArguments(t, nodeByTask)
+		verr.InvalidArgumentReferences = append(verr.InvalidArgumentReferences, invalid...)
+		nodeByTask[t.Name].Arguments = resolved
+	}
+
+	if !verr.empty() {
+		return nil, verr
+	}
+
+	var edges []Edge
+	for _, t := range d.Tasks {
+		for _, dep := range t.Dependencies {
+			edges = append(edges, Edge{
+				From: nodeByTask[dep].key(),
+				To:   nodeByTask[t.Name].key(),
+			})
+		}
+	}
+
+	wf, err := NewWorkflow(d.Name, nodes, edges)
+	if err != nil {
+		return nil, fmt.Errorf("orchid: compiling DAG %q: %w", d.Name, err)
+	}
+
+	wf.Targets = make([]string, len(targets))
+	for i, target := range targets {
+		wf.Targets[i] = nodeByTask[target].key()
+	}
+
+	return wf, nil
+}
+
+// dagArgRefPattern matches a "{{tasks.<name>.outputs.<key>}}" reference
+// inside a DAGTask.Arguments value.
+var dagArgRefPattern = regexp.MustCompile(`\{\{tasks\.([\w-]+)\.outputs\.([\w-]+)\}\}`)
+
+// resolveDAGArguments rewrites every "{{tasks.<name>.outputs.<key>}}"
+// reference in t's Arguments into the "{{nodes.<node-key>.outputs.<key>}}"
+// form the runtime engine resolves against a Node's output (see Node.key -
+// using the dependency's ActivityName here would be ambiguous whenever two
+// sibling tasks share an Activity), and reports any
+// reference to a task that either doesn't exist or isn't a declared
+// dependency of t (the data dependency must also be a scheduling dependency,
+// or the upstream output could still be missing when t runs). Literal
+// values, and values with no "{{tasks...}}" reference, pass through
+// unchanged.
+func resolveDAGArguments(t DAGTask, nodeByTask map[string]*Node) (map[string]string, []string) {
+	if len(t.Arguments) == 0 {
+		return nil, nil
+	}
+
+	var invalid []string
+	resolved := make(map[string]string, len(t.Arguments))
+	for k, v := range t.Arguments {
+		resolved[k] = dagArgRefPattern.ReplaceAllStringFunc(v, func(match string) string {
+			groups := dagArgRefPattern.FindStringSubmatch(match)
+			depName, outputKey := groups[1], groups[2]
+
+			depNode, ok := nodeByTask[depName]
+			if !ok {
+				invalid = append(invalid, fmt.Sprintf("%s.%s: references unknown task %q", t.Name, k, depName))
+				return match
+			}
+			if !containsString(t.Dependencies, depName) {
+				invalid = append(invalid, fmt.Sprintf("%s.%s: references %q, which is not a declared dependency", t.Name, k, depName))
+				return match
+			}
+
+			return fmt.Sprintf("{{nodes.%s.outputs.%s}}", depNode.key(), outputKey)
+		})
+	}
+	return resolved, invalid
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// detectDAGCycle returns the task names participating in a dependency cycle,
+// or nil if the DAG is acyclic. Unknown dependency names are ignored here;
+// they're reported separately by Compile.
+func detectDAGCycle(tasks []DAGTask, byName map[string]*DAGTask) []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int, len(tasks))
+	var cycle []string
+	// closed is set once unwinding reaches the task that closed the cycle, so
+	// tasks that merely depend on the cycle aren't reported as members.
+	closed := false
+
+	var visit func(name string) bool
+	visit = func(name string) bool {
+		switch state[name] {
+		case visited:
+			return false
+		case visiting:
+			cycle = append(cycle, name)
+			return true
+		}
+
+		state[name] = visiting
+		task, ok := byName[name]
+		if ok {
+			for _, dep := range task.Dependencies {
+				if _, known := byName[dep]; !known {
+					continue
+				}
+				if visit(dep) {
+					if !closed {
+						if cycle[0] == name {
+							closed = true
+						} else {
+							cycle = append(cycle, name)
+						}
+					}
+					return true
+				}
+			}
+		}
+		state[name] = visited
+		return false
+	}
+
+	for _, t := range tasks {
+		if state[t.Name] == unvisited {
+			if visit(t.Name) {
+				break
+			}
+		}
+	}
+
+	return cycle
+}
+
+// leafDAGTasks returns the names of tasks that no other task depends on.
+func leafDAGTasks(tasks []DAGTask) []string {
+	hasDependent := make(map[string]bool, len(tasks))
+	for _, t := range tasks {
+		for _, dep := range t.Dependencies {
+			hasDependent[dep] = true
+		}
+	}
+
+	var leaves []string
+	for _, t := range tasks {
+		if !hasDependent[t.Name] {
+			leaves = append(leaves, t.Name)
+		}
+	}
+	return leaves
+}
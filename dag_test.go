@@ -0,0 +1,190 @@
+// Copyright 2024 Kyodo Tech合同会社
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchid
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestWorkflowDAGCompileFanOutSharedActivity guards against Compile failing
+// on the headline fan-out scenario: sibling tasks that invoke the same
+// Activity with different Arguments. Before Node.Key existed, both compiled
+// Nodes shared the ActivityName "Process" and NewWorkflow's byKey
+// uniqueness check rejected the second one outright.
+func TestWorkflowDAGCompileFanOutSharedActivity(t *testing.T) {
+	dag := WorkflowDAG{
+		Name: "fan-out",
+		Tasks: []DAGTask{
+			{Name: "start", Activity: "Start"},
+			{Name: "p1", Activity: "Process", Dependencies: []string{"start"}, Arguments: map[string]string{"mode": "a"}},
+			{Name: "p2", Activity: "Process", Dependencies: []string{"start"}, Arguments: map[string]string{"mode": "b"}},
+		},
+	}
+
+	wf, err := dag.Compile()
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	if len(wf.Nodes) != 3 {
+		t.Fatalf("len(wf.Nodes) = %d, want 3", len(wf.Nodes))
+	}
+
+	var p1, p2 *Node
+	for _, node := range wf.Nodes {
+		switch node.Key {
+		case "p1":
+			p1 = node
+		case "p2":
+			p2 = node
+		}
+	}
+	if p1 == nil || p2 == nil {
+		t.Fatalf("Compile() wf.Nodes = %+v, want nodes keyed \"p1\" and \"p2\"", wf.Nodes)
+	}
+	if p1.ActivityName != "Process" || p2.ActivityName != "Process" {
+		t.Fatalf("p1.ActivityName = %q, p2.ActivityName = %q, want both \"Process\"", p1.ActivityName, p2.ActivityName)
+	}
+	if p1.Arguments["mode"] != "a" || p2.Arguments["mode"] != "b" {
+		t.Fatalf("p1.Arguments = %v, p2.Arguments = %v, want mode \"a\"/\"b\" preserved per task", p1.Arguments, p2.Arguments)
+	}
+
+	if !wf.isParallelNode(p1) || !wf.isParallelNode(p2) {
+		t.Fatal("Compile() did not classify the fan-out siblings as parallel nodes")
+	}
+
+	dot := string(wf.ExportDot("    ", nil))
+	if !strings.Contains(dot, `"p1"`) || !strings.Contains(dot, `"p2"`) {
+		t.Fatalf("ExportDot() = %q, want distinct \"p1\" and \"p2\" nodes", dot)
+	}
+}
+
+func TestDetectDAGCycle(t *testing.T) {
+	tests := []struct {
+		name     string
+		tasks    []DAGTask
+		wantNone bool
+	}{
+		{
+			name: "acyclic",
+			tasks: []DAGTask{
+				{Name: "a"},
+				{Name: "b", Dependencies: []string{"a"}},
+				{Name: "c", Dependencies: []string{"a", "b"}},
+			},
+			wantNone: true,
+		},
+		{
+			name: "direct cycle",
+			tasks: []DAGTask{
+				{Name: "a", Dependencies: []string{"b"}},
+				{Name: "b", Dependencies: []string{"a"}},
+			},
+		},
+		{
+			name: "self cycle",
+			tasks: []DAGTask{
+				{Name: "a", Dependencies: []string{"a"}},
+			},
+		},
+		{
+			name: "transitive cycle",
+			tasks: []DAGTask{
+				{Name: "a", Dependencies: []string{"c"}},
+				{Name: "b", Dependencies: []string{"a"}},
+				{Name: "c", Dependencies: []string{"b"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			byName := make(map[string]*DAGTask, len(tt.tasks))
+			for i := range tt.tasks {
+				byName[tt.tasks[i].Name] = &tt.tasks[i]
+			}
+
+			cycle := detectDAGCycle(tt.tasks, byName)
+			if tt.wantNone && len(cycle) != 0 {
+				t.Fatalf("detectDAGCycle() = %v, want none", cycle)
+			}
+			if !tt.wantNone && len(cycle) == 0 {
+				t.Fatalf("detectDAGCycle() = nil, want a cycle")
+			}
+		})
+	}
+}
+
+func TestResolveDAGArguments(t *testing.T) {
+	nodeByTask := map[string]*Node{
+		"fetch":   {ActivityName: "FetchActivity"},
+		"process": {ActivityName: "ProcessActivity"},
+	}
+
+	t.Run("resolves a declared dependency reference", func(t *testing.T) {
+		task := DAGTask{
+			Name:         "process",
+			Dependencies: []string{"fetch"},
+			Arguments:    map[string]string{"input": "{{tasks.fetch.outputs.result}}"},
+		}
+
+		resolved, invalid := resolveDAGArguments(task, nodeByTask)
+		if len(invalid) != 0 {
+			t.Fatalf("unexpected invalid references: %v", invalid)
+		}
+		want := "{{nodes.FetchActivity.outputs.result}}"
+		if resolved["input"] != want {
+			t.Fatalf("resolved[%q] = %q, want %q", "input", resolved["input"], want)
+		}
+	})
+
+	t.Run("passes literal values through unchanged", func(t *testing.T) {
+		task := DAGTask{Name: "process", Arguments: map[string]string{"mode": "fast"}}
+
+		resolved, invalid := resolveDAGArguments(task, nodeByTask)
+		if len(invalid) != 0 {
+			t.Fatalf("unexpected invalid references: %v", invalid)
+		}
+		if resolved["mode"] != "fast" {
+			t.Fatalf("resolved[%q] = %q, want %q", "mode", resolved["mode"], "fast")
+		}
+	})
+
+	t.Run("rejects a reference to an undeclared dependency", func(t *testing.T) {
+		task := DAGTask{
+			Name:      "process",
+			Arguments: map[string]string{"input": "{{tasks.fetch.outputs.result}}"},
+		}
+
+		_, invalid := resolveDAGArguments(task, nodeByTask)
+		if len(invalid) != 1 {
+			t.Fatalf("invalid = %v, want exactly one entry", invalid)
+		}
+	})
+
+	t.Run("rejects a reference to an unknown task", func(t *testing.T) {
+		task := DAGTask{
+			Name:         "process",
+			Dependencies: []string{"fetch"},
+			Arguments:    map[string]string{"input": "{{tasks.missing.outputs.result}}"},
+		}
+
+		_, invalid := resolveDAGArguments(task, nodeByTask)
+		if len(invalid) != 1 {
+			t.Fatalf("invalid = %v, want exactly one entry", invalid)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+// Copyright 2024 Kyodo Tech合同会社
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchid
+
+import (
+	"context"
+	"fmt"
+)
+
+// SetOnExit sets wf's OnExit handler, rejecting node if its identity (see
+// Node.key) collides with an existing main-graph node: ExportDot/
+// ExportMermaid key nodes by identity, so a collision would silently merge
+// the two into one half-styled node instead of rendering OnExit in its own
+// cluster. onExit is unexported precisely so this check can't be bypassed by
+// a direct field assignment.
+func (wf *Workflow) SetOnExit(node *Node) error {
+	if _, dup := wf.byKey[node.key()]; dup {
+		return fmt.Errorf("orchid: OnExit node %q collides with an existing node", node.key())
+	}
+	wf.onExit = node
+	return nil
+}
+
+// isExitNode reports whether node is wf's exit handler, i.e. the node
+// attached via SetOnExit rather than part of the main graph. Exit nodes are
+// excluded from isStartNode/isParallelNode classification and are rendered
+// separately by ExportDot/ExportMermaid.
+//
+// Compared by pointer identity rather than ID: OnExit is assigned by
+// SetOnExit, not NewWorkflow, so it never passes through NewWorkflow's
+// ID-assignment loop and its ID stays the zero value, which would otherwise
+// collide with whichever main-graph node happens to get ID 0.
+func (wf *Workflow) isExitNode(node *Node) bool {
+	return wf.onExit != nil && node == wf.onExit
+}
+
+// ExitHandler runs wf's OnExit Node. finalPhase is the terminal Phase of the
+// main graph - it may be PhaseFailed or PhaseError as well as
+// PhaseSucceeded, since OnExit fires regardless of outcome.
+type ExitHandler func(ctx context.Context, wf *Workflow, finalPhase Phase) error
+
+// RunOnExit invokes handler for wf's OnExit Node, doing nothing if none is
+// set. It's the point the execution engine calls once every Node in the main
+// graph has reached a terminal phase; ExportDot/ExportMermaid only render
+// OnExit, they don't run it.
+func (wf *Workflow) RunOnExit(ctx context.Context, finalPhase Phase, handler ExitHandler) error {
+	if wf.onExit == nil {
+		return nil
+	}
+	return handler(ctx, wf, finalPhase)
+}
@@ -0,0 +1,91 @@
+// Copyright 2024 Kyodo Tech合同会社
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchid
+
+import (
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// startingGraphNodes returns the graph.Node view of every Node in wf with no
+// incoming edge, i.e. its entry points.
+func (wf *Workflow) startingGraphNodes() []graph.Node {
+	var starts []graph.Node
+	it := wf.directedGraph.Nodes()
+	for it.Next() {
+		n := it.Node()
+		if wf.directedGraph.To(n.ID()).Len() == 0 {
+			starts = append(starts, n)
+		}
+	}
+	return starts
+}
+
+// startingNodes is startingGraphNodes resolved back to *Node.
+func (wf *Workflow) startingNodes() []*Node {
+	var starts []*Node
+	for _, n := range wf.startingGraphNodes() {
+		if node := wf.nodeByID(n.ID()); node != nil {
+			starts = append(starts, node)
+		}
+	}
+	return starts
+}
+
+// exitNodes returns the terminal Nodes of the main graph, i.e. every Node
+// with no outgoing edge. wf.OnExit is never a member of wf.directedGraph
+// (see isExitNode), so it never appears in the result - callers still guard
+// with isExitNode defensively, since this walks the graph rather than
+// special-casing OnExit directly.
+func (wf *Workflow) exitNodes() []*Node {
+	var exits []*Node
+	it := wf.directedGraph.Nodes()
+	for it.Next() {
+		n := it.Node()
+		if wf.directedGraph.From(n.ID()).Len() == 0 {
+			if node := wf.nodeByID(n.ID()); node != nil {
+				exits = append(exits, node)
+			}
+		}
+	}
+	return exits
+}
+
+// spawningParallelNodes returns the ID of every Node with more than one
+// outgoing edge, i.e. the nodes that fan out into parallel branches.
+func (wf *Workflow) spawningParallelNodes() []int64 {
+	var spawners []int64
+	it := wf.directedGraph.Nodes()
+	for it.Next() {
+		n := it.Node()
+		if wf.directedGraph.From(n.ID()).Len() > 1 {
+			spawners = append(spawners, n.ID())
+		}
+	}
+	return spawners
+}
+
+// markParallelNodes returns the set of node IDs reachable directly from
+// spawners, i.e. the nodes that run in parallel with at least one sibling.
+func markParallelNodes(g *simple.DirectedGraph, spawners []int64) map[int64]bool {
+	parallel := make(map[int64]bool)
+	for _, id := range spawners {
+		to := g.From(id)
+		for to.Next() {
+			parallel[to.Node().ID()] = true
+		}
+	}
+	return parallel
+}
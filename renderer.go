@@ -0,0 +1,357 @@
+// Copyright 2024 Kyodo Tech合同会社
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-graphviz"
+)
+
+// RenderOptions configures a Renderer invocation. It gathers the parameters
+// ExportDot/ExportMermaid and their *WithStatus variants take individually
+// into one struct so a Renderer implementation only has to accept one type.
+type RenderOptions struct {
+	OptionalChildWorkflows map[string]*Workflow
+	NodeToMetadata         map[string]NodeMetadata
+	NodeStatus             map[string]NodeStatus
+	EdgeStatus             map[string]EdgeStatus
+	Theme                  Theme
+
+	// GraphvizPath overrides the "dot" binary SVGRenderer/PNGRenderer shell
+	// out to. Defaults to "dot" on PATH.
+	GraphvizPath string
+
+	// Timeout bounds how long SVGRenderer/PNGRenderer wait for graphviz,
+	// CLI or pure-Go. Defaults to 10s.
+	Timeout time.Duration
+}
+
+// Theme overrides the hardcoded colors ExportDot/ExportMermaid use for each
+// node class, keyed the same way as the Mermaid classDef names. Empty fields,
+// including every field of the zero value, fall back to DefaultTheme()'s
+// colors.
+type Theme struct {
+	StartNodeColor    string
+	ParallelNodeColor string
+	ExitNodeColor     string
+}
+
+// DefaultTheme returns the palette ExportDot/ExportMermaid have always used.
+func DefaultTheme() Theme {
+	return Theme{StartNodeColor: "green", ParallelNodeColor: "lightblue", ExitNodeColor: "yellow"}
+}
+
+func (t Theme) orDefault() Theme {
+	def := DefaultTheme()
+	if t.StartNodeColor == "" {
+		t.StartNodeColor = def.StartNodeColor
+	}
+	if t.ParallelNodeColor == "" {
+		t.ParallelNodeColor = def.ParallelNodeColor
+	}
+	if t.ExitNodeColor == "" {
+		t.ExitNodeColor = def.ExitNodeColor
+	}
+	return t
+}
+
+// Renderer produces a byte representation of a Workflow in some output
+// format, so callers aren't limited to the DOT/Mermaid methods hardcoded
+// onto Workflow.
+type Renderer interface {
+	Render(wf *Workflow, opts RenderOptions) ([]byte, error)
+	ContentType() string
+}
+
+// DotRenderer renders a Workflow as Graphviz DOT. It's equivalent to
+// Workflow.ExportDotWithStatus, plus Theme support.
+type DotRenderer struct{}
+
+func (DotRenderer) Render(wf *Workflow, opts RenderOptions) ([]byte, error) {
+	indent := "    "
+	dotData := wf.ExportDotWithStatus(indent, opts.OptionalChildWorkflows, opts.NodeToMetadata, opts.NodeStatus, opts.EdgeStatus)
+	return applyDotTheme(dotData, opts.Theme.orDefault()), nil
+}
+
+func (DotRenderer) ContentType() string { return "text/vnd.graphviz" }
+
+// dotStartColorPattern, dotParallelFillPattern and dotExitFillPattern anchor
+// applyDotTheme's replacements on word boundaries so "color=green" (the
+// start-node border color) doesn't also match inside "fillcolor=green" (the
+// PhaseSucceeded fill color dotPhaseFillColor emits).
+var (
+	dotStartColorPattern   = regexp.MustCompile(`\bcolor=green\b`)
+	dotParallelFillPattern = regexp.MustCompile(`\bfillcolor=lightblue\b`)
+	dotExitFillPattern     = regexp.MustCompile(`\bfillcolor=yellow\b`)
+)
+
+// applyDotTheme rewrites the hardcoded DOT colors to the ones configured on
+// theme, doing nothing when theme is the default palette.
+func applyDotTheme(dotData []byte, theme Theme) []byte {
+	if theme == DefaultTheme() {
+		return dotData
+	}
+
+	s := string(dotData)
+	s = dotStartColorPattern.ReplaceAllStringFunc(s, func(string) string { return "color=" + theme.StartNodeColor })
+	s = dotParallelFillPattern.ReplaceAllStringFunc(s, func(string) string { return "fillcolor=" + theme.ParallelNodeColor })
+	s = dotExitFillPattern.ReplaceAllStringFunc(s, func(string) string { return "fillcolor=" + theme.ExitNodeColor })
+	return []byte(s)
+}
+
+// MermaidRenderer renders a Workflow as Mermaid flowchart syntax. It's
+// equivalent to Workflow.ExportMermaidWithStatus, plus Theme support.
+type MermaidRenderer struct{}
+
+func (MermaidRenderer) Render(wf *Workflow, opts RenderOptions) ([]byte, error) {
+	indent := "    "
+	mermaidData := wf.ExportMermaidWithStatus(indent, opts.OptionalChildWorkflows, opts.NodeToMetadata, opts.NodeStatus, opts.EdgeStatus)
+	return applyMermaidTheme(mermaidData, opts.Theme.orDefault()), nil
+}
+
+func (MermaidRenderer) ContentType() string { return "text/vnd.mermaid" }
+
+// applyMermaidTheme rewrites the hardcoded Mermaid classDef colors to the
+// ones configured on theme, doing nothing when theme is the default
+// palette.
+func applyMermaidTheme(mermaidData []byte, theme Theme) []byte {
+	if theme == DefaultTheme() {
+		return mermaidData
+	}
+
+	s := string(mermaidData)
+	s = strings.ReplaceAll(s, "classDef startNode fill:#9f6", "classDef startNode fill:"+theme.StartNodeColor)
+	s = strings.ReplaceAll(s, "classDef parallelNode fill:#6cf", "classDef parallelNode fill:"+theme.ParallelNodeColor)
+	s = strings.ReplaceAll(s, "classDef exitNode fill:#fe6", "classDef exitNode fill:"+theme.ExitNodeColor)
+	return []byte(s)
+}
+
+// graphvizRenderer rasterizes DOT output into format by shelling out to the
+// Graphviz "dot" CLI, falling back to the pure-Go github.com/goccy/go-graphviz
+// whenever the CLI fails (not installed, timed out, or exited with an error).
+type graphvizRenderer struct {
+	format string // "svg" or "png"
+}
+
+func (r graphvizRenderer) Render(wf *Workflow, opts RenderOptions) ([]byte, error) {
+	dotData, err := (DotRenderer{}).Render(wf, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	out, cliErr := runGraphvizCLI(dotData, r.format, opts)
+	if cliErr == nil {
+		return out, nil
+	}
+
+	out, fallbackErr := runGraphvizGo(dotData, r.format, opts)
+	if fallbackErr != nil {
+		return nil, fmt.Errorf("orchid: rendering %s: dot CLI failed (%v), pure-Go fallback failed: %w", r.format, cliErr, fallbackErr)
+	}
+	return out, nil
+}
+
+func (r graphvizRenderer) ContentType() string {
+	if r.format == "png" {
+		return "image/png"
+	}
+	return "image/svg+xml"
+}
+
+// SVGRenderer renders a Workflow to SVG.
+var SVGRenderer Renderer = graphvizRenderer{format: "svg"}
+
+// PNGRenderer renders a Workflow to PNG.
+var PNGRenderer Renderer = graphvizRenderer{format: "png"}
+
+func runGraphvizCLI(dotData []byte, format string, opts RenderOptions) ([]byte, error) {
+	dotPath := opts.GraphvizPath
+	if dotPath == "" {
+		dotPath = "dot"
+	}
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, dotPath, "-T"+format)
+	cmd.Stdin = bytes.NewReader(dotData)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+// runGraphvizGo renders dotData without shelling out, for environments
+// without a Graphviz install.
+func runGraphvizGo(dotData []byte, format string, opts RenderOptions) ([]byte, error) {
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	g, err := graphviz.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer g.Close()
+
+	graph, err := graphviz.ParseBytes(dotData)
+	if err != nil {
+		return nil, err
+	}
+	defer graph.Close()
+
+	gvFormat := graphviz.SVG
+	if format == "png" {
+		gvFormat = graphviz.PNG
+	}
+
+	var buf bytes.Buffer
+	if err := g.Render(ctx, graph, gvFormat, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// jsonNode is the shape of a single node in JSONRenderer's output, matching
+// what Cytoscape.js and d3 force-directed layouts expect.
+type jsonNode struct {
+	ID       string                 `json:"id"`
+	Label    string                 `json:"label"`
+	Class    string                 `json:"class,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+type jsonEdge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Class string `json:"class,omitempty"`
+}
+
+type jsonGraph struct {
+	Nodes []jsonNode `json:"nodes"`
+	Edges []jsonEdge `json:"edges"`
+}
+
+// JSONRenderer emits a {nodes, edges} structure compatible with Cytoscape.js
+// and d3, for frontends that want to lay the graph out themselves instead
+// of parsing DOT or Mermaid.
+type JSONRenderer struct{}
+
+func (JSONRenderer) Render(wf *Workflow, opts RenderOptions) ([]byte, error) {
+	var graph jsonGraph
+
+	for _, node := range wf.Nodes {
+		if wf.isExitNode(node) {
+			continue
+		}
+		graph.Nodes = append(graph.Nodes, jsonNode{
+			ID:       node.key(),
+			Label:    node.key(),
+			Class:    jsonNodeClass(wf, node),
+			Metadata: jsonNodeMetadata(opts, node),
+		})
+	}
+
+	if wf.onExit != nil {
+		graph.Nodes = append(graph.Nodes, jsonNode{
+			ID:       wf.onExit.key(),
+			Label:    wf.onExit.key(),
+			Class:    "exitNode",
+			Metadata: jsonNodeMetadata(opts, wf.onExit),
+		})
+	}
+
+	for _, edge := range wf.Edges {
+		graph.Edges = append(graph.Edges, jsonEdge{From: edge.From, To: edge.To, Class: jsonEdgeClass(opts, edge.From, edge.To)})
+	}
+
+	if wf.onExit != nil {
+		for _, terminal := range wf.exitNodes() {
+			if wf.isExitNode(terminal) {
+				continue
+			}
+			graph.Edges = append(graph.Edges, jsonEdge{From: terminal.key(), To: wf.onExit.key(), Class: "exitEdge"})
+		}
+	}
+
+	return json.Marshal(graph)
+}
+
+func (JSONRenderer) ContentType() string { return "application/json" }
+
+// jsonNodeClass mirrors the same classification ExportDot/ExportMermaid use,
+// so a frontend consuming JSONRenderer's output can apply equivalent
+// styling.
+func jsonNodeClass(wf *Workflow, node *Node) string {
+	switch {
+	case wf.isExitNode(node):
+		return "exitNode"
+	case wf.isStartNode(node):
+		return "startNode"
+	case wf.isParallelNode(node):
+		return "parallelNode"
+	default:
+		return ""
+	}
+}
+
+func jsonNodeMetadata(opts RenderOptions, node *Node) map[string]interface{} {
+	meta := make(map[string]interface{})
+
+	if m, ok := opts.NodeToMetadata[node.key()]; ok && m.Description != "" {
+		meta["description"] = m.Description
+	}
+	if s, ok := opts.NodeStatus[node.key()]; ok {
+		meta["phase"] = s.Phase
+		meta["attempts"] = s.Attempts
+		if s.Message != "" {
+			meta["message"] = s.Message
+		}
+	}
+
+	if len(meta) == 0 {
+		return nil
+	}
+	return meta
+}
+
+func jsonEdgeClass(opts RenderOptions, from, to string) string {
+	if status, ok := opts.EdgeStatus[edgeStatusKey(from, to)]; ok && status.Traversed {
+		return "traversed"
+	}
+	return ""
+}
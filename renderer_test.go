@@ -0,0 +1,52 @@
+// Copyright 2024 Kyodo Tech合同会社
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchid
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyDotTheme(t *testing.T) {
+	theme := Theme{StartNodeColor: "blue", ParallelNodeColor: "orange", ExitNodeColor: "red"}
+
+	dotData := []byte(`"a" [shape=doublecircle, color=green];
+"b" [style=filled, fillcolor=lightblue];
+"c" [style=filled, fillcolor=green, tooltip="PhaseSucceeded"];
+`)
+
+	got := string(applyDotTheme(dotData, theme))
+
+	if !strings.Contains(got, "color=blue") {
+		t.Errorf("start node color not rewritten: %s", got)
+	}
+	if !strings.Contains(got, "fillcolor=orange") {
+		t.Errorf("parallel node fill not rewritten: %s", got)
+	}
+	if !strings.Contains(got, "fillcolor=green") {
+		t.Errorf("PhaseSucceeded fill color was rewritten, should be left alone: %s", got)
+	}
+	if strings.Contains(got, "color=blue, tooltip") {
+		t.Errorf("rewrite bled into the status fillcolor: %s", got)
+	}
+}
+
+func TestApplyDotThemeDefaultIsNoop(t *testing.T) {
+	dotData := []byte(`"a" [shape=doublecircle, color=green];`)
+	got := applyDotTheme(dotData, DefaultTheme())
+	if string(got) != string(dotData) {
+		t.Errorf("applyDotTheme with DefaultTheme() changed the input: %s", got)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2024 Kyodo Tech合同会社
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchid
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// Node is a single unit of work in a Workflow: the activity it runs plus the
+// arguments passed to it. ID identifies it in the underlying directed graph
+// and is assigned by NewWorkflow; ActivityName is the activity it runs.
+//
+// Key, if set, is node's identity for byKey uniqueness, Edge.From/To
+// resolution, and DOT/Mermaid/JSON node ids, instead of ActivityName.
+// WorkflowDAG.Compile sets it to the DAGTask.Name so sibling tasks that
+// invoke the same Activity - the parallel fan-out case - get distinct graph
+// identities, since two Nodes can't otherwise coexist under the same
+// ActivityName. Workflows built by hand can leave it unset, in which case
+// it defaults to ActivityName and every reference in this package
+// documented as "by ActivityName" behaves exactly as before.
+type Node struct {
+	ID           int64
+	ActivityName string
+	Key          string
+	Arguments    map[string]string
+
+	// EditLink, if set, is rendered as a clickable link on the node by
+	// ExportMermaidHTML.
+	EditLink *string
+}
+
+// key returns node's identity: Key if set, else ActivityName.
+func (n *Node) key() string {
+	if n.Key != "" {
+		return n.Key
+	}
+	return n.ActivityName
+}
+
+// Edge is a directed dependency between two nodes, named by Node identity
+// (see Node.key) rather than by ID so callers building a Workflow by hand
+// don't need to track Node IDs themselves.
+type Edge struct {
+	From string
+	To   string
+}
+
+// Workflow is a named directed graph of Nodes connected by Edges, plus an
+// optional OnExit handler. ExportDot/ExportMermaid and the Renderer
+// implementations all operate on a compiled *Workflow.
+type Workflow struct {
+	Name  string
+	Nodes []*Node
+	Edges []Edge
+
+	// Targets names the terminal Nodes a WorkflowDAG was compiled for, by
+	// Node identity (see Node.key). Nil for Workflows built directly rather
+	// than through WorkflowDAG.Compile.
+	Targets []string
+
+	directedGraph *simple.DirectedGraph
+	byKey         map[string]*Node
+
+	// onExit, if set, names the Node the runtime engine runs once every
+	// other Node in the graph has reached a terminal phase, regardless of
+	// whether any of them failed. It's excluded from isStartNode/
+	// isParallelNode classification and rendered in its own cluster by
+	// ExportDot/ExportMermaid; RunOnExit is the actual engine wiring point.
+	// Unexported and set only via SetOnExit, which rejects a Node whose
+	// identity collides with an existing main-graph Node - a direct,
+	// unchecked assignment would bypass that check entirely.
+	onExit *Node
+}
+
+// OnExit returns wf's exit handler Node, or nil if none is set. See SetOnExit.
+func (wf *Workflow) OnExit() *Node {
+	return wf.onExit
+}
+
+// NewWorkflow builds a Workflow from nodes and edges, assigning each Node an
+// ID and constructing the directed graph that isStartNode/isParallelNode/
+// startingGraphNodes/exitNodes use to answer structural questions. Nodes are
+// keyed by Node.key (ActivityName unless Key is set) for uniqueness and
+// Edge resolution; edges naming an unknown key are rejected.
+func NewWorkflow(name string, nodes []*Node, edges []Edge) (*Workflow, error) {
+	wf := &Workflow{
+		Name:          name,
+		Nodes:         nodes,
+		Edges:         edges,
+		directedGraph: simple.NewDirectedGraph(),
+		byKey:         make(map[string]*Node, len(nodes)),
+	}
+
+	for i, node := range nodes {
+		node.ID = int64(i)
+		key := node.key()
+		if _, dup := wf.byKey[key]; dup {
+			return nil, fmt.Errorf("orchid: duplicate node %q", key)
+		}
+		wf.byKey[key] = node
+		wf.directedGraph.AddNode(simple.Node(node.ID))
+	}
+
+	for _, edge := range edges {
+		from, ok := wf.byKey[edge.From]
+		if !ok {
+			return nil, fmt.Errorf("orchid: edge references unknown node %q", edge.From)
+		}
+		to, ok := wf.byKey[edge.To]
+		if !ok {
+			return nil, fmt.Errorf("orchid: edge references unknown node %q", edge.To)
+		}
+		wf.directedGraph.SetEdge(wf.directedGraph.NewEdge(simple.Node(from.ID), simple.Node(to.ID)))
+	}
+
+	return wf, nil
+}
+
+func (wf *Workflow) nodeByID(id int64) *Node {
+	for _, node := range wf.Nodes {
+		if node.ID == id {
+			return node
+		}
+	}
+	return nil
+}
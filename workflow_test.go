@@ -0,0 +1,69 @@
+// Copyright 2024 Kyodo Tech合同会社
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchid
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestOnExitDoesNotHideFirstNode guards against isExitNode comparing by ID:
+// OnExit is assigned by SetOnExit and never passes through
+// NewWorkflow's ID-assignment loop, so it keeps the zero value and would
+// otherwise collide with the first node ever added to the graph.
+func TestOnExitDoesNotHideFirstNode(t *testing.T) {
+	wf, err := NewWorkflow("wf", []*Node{{ActivityName: "first"}, {ActivityName: "second"}}, []Edge{
+		{From: "first", To: "second"},
+	})
+	if err != nil {
+		t.Fatalf("NewWorkflow() error = %v", err)
+	}
+	if err := wf.SetOnExit(&Node{ActivityName: "cleanup"}); err != nil {
+		t.Fatalf("SetOnExit() error = %v", err)
+	}
+
+	if wf.isExitNode(wf.Nodes[0]) {
+		t.Fatalf("isExitNode(%q) = true, want false", wf.Nodes[0].ActivityName)
+	}
+	if !wf.isStartNode(wf.Nodes[0]) {
+		t.Fatalf("isStartNode(%q) = false, want true", wf.Nodes[0].ActivityName)
+	}
+
+	dot := string(wf.ExportDot("    ", nil))
+	if !strings.Contains(dot, `"first"`) {
+		t.Fatalf("ExportDot output dropped node %q: %s", "first", dot)
+	}
+	if !strings.Contains(dot, `"second"`) {
+		t.Fatalf("ExportDot output dropped node %q: %s", "second", dot)
+	}
+}
+
+// TestSetOnExitRejectsCollisionWithMainGraph guards against OnExit silently
+// merging with a main-graph node that happens to share its identity: once
+// that happened, ExportDot/ExportMermaid would render one half-styled node
+// instead of two.
+func TestSetOnExitRejectsCollisionWithMainGraph(t *testing.T) {
+	wf, err := NewWorkflow("wf", []*Node{{ActivityName: "first"}}, nil)
+	if err != nil {
+		t.Fatalf("NewWorkflow() error = %v", err)
+	}
+
+	if err := wf.SetOnExit(&Node{ActivityName: "first"}); err == nil {
+		t.Fatal("SetOnExit() error = nil, want an error for a node colliding with an existing node")
+	}
+	if wf.OnExit() != nil {
+		t.Fatalf("SetOnExit() left wf.OnExit() = %v, want nil after a rejected collision", wf.OnExit())
+	}
+}